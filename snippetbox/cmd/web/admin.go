@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"snipeetbox.porcelain.com/internal/models"
+)
+
+// userList displays every registered user
+func (app *application) userList(w http.ResponseWriter, r *http.Request) {
+	if !app.requireRole(w, r, models.RoleAdmin) {
+		return
+	}
+
+	users, err := app.users.List()
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	for _, u := range users {
+		fmt.Fprintf(w, "%+v\n", u)
+	}
+}
+
+// userDeletePost removes a user
+func (app *application) userDeletePost(w http.ResponseWriter, r *http.Request) {
+	if !app.requireRole(w, r, models.RoleAdmin) {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	err = app.users.Delete(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"snipeetbox.porcelain.com/internal/models"
+)
+
+// clientIP returns the requesting client's IP address, stripping the port
+// from RemoteAddr
+func clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+func (app *application) userSignup(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, "Display a form for signing up a new user")
+}
+
+func (app *application) userSignupPost(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	name := r.PostForm.Get("name")
+	email := r.PostForm.Get("email")
+	password := r.PostForm.Get("password")
+
+	err = app.users.Insert(name, email, password)
+	if err != nil {
+		if errors.Is(err, models.ErrDuplicateEmail) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, "Display the signup form again: email address is already in use")
+			return
+		}
+		if errors.Is(err, models.ErrInvalidEmail) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, "Display the signup form again: email address is invalid")
+			return
+		}
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Your signup was successful. Please log in.")
+
+	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+}
+
+func (app *application) userLogin(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, "Display a form for logging in")
+}
+
+func (app *application) userLoginPost(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	email := r.PostForm.Get("email")
+	password := r.PostForm.Get("password")
+
+	id, err := app.users.Authenticate(email, password, clientIP(r))
+	if err != nil {
+		if errors.Is(err, models.ErrAccountLocked) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, "Display the login form again: too many failed attempts, try again later")
+			return
+		}
+		if errors.Is(err, models.ErrTOTPRequired) {
+			err = app.sessionManager.RenewToken(r.Context())
+			if err != nil {
+				app.serverError(w, r, err)
+				return
+			}
+
+			// Half-authenticated: the password matched, but the session
+			// isn't promoted to authenticatedUserID until the second
+			// factor is verified by userLoginTwoFactorPost
+			app.sessionManager.Put(r.Context(), "pendingTOTPUserID", id)
+
+			http.Redirect(w, r, "/user/login/totp", http.StatusSeeOther)
+			return
+		}
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, "Display the login form again: email or password is incorrect")
+			return
+		}
+		app.serverError(w, r, err)
+		return
+	}
+
+	err = app.sessionManager.RenewToken(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "authenticatedUserID", id)
+
+	path := app.sessionManager.PopString(r.Context(), "redirectPathAfterLogin")
+	if path != "" {
+		http.Redirect(w, r, path, http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/snippet/create", http.StatusSeeOther)
+}
+
+func (app *application) userLogout(w http.ResponseWriter, r *http.Request) {
+	err := app.sessionManager.RenewToken(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Remove(r.Context(), "authenticatedUserID")
+	app.sessionManager.Put(r.Context(), "flash", "You've been logged out successfully.")
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
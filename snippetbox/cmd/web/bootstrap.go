@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snipeetbox.porcelain.com/internal/models"
+)
+
+// loginAttemptPruneInterval is how often startLoginAttemptPruner asks
+// UserModel to delete stale login attempt rows
+const loginAttemptPruneInterval = 24 * time.Hour
+
+// startLoginAttemptPruner runs UserModel.PruneOldLoginAttempts on a ticker
+// for as long as the process is alive. It's started once from main.
+func startLoginAttemptPruner(users *models.UserModel, logger *slog.Logger) {
+	ticker := time.NewTicker(loginAttemptPruneInterval)
+
+	go func() {
+		for range ticker.C {
+			if err := users.PruneOldLoginAttempts(); err != nil {
+				logger.Error("failed to prune login attempts", "error", err)
+			}
+		}
+	}()
+}
+
+// bootstrapAdmin promotes the user with the given email to the admin role.
+// It's called from main on startup when the -admin-email flag is set, so an
+// operator can always regain admin access without touching the database
+// directly.
+func bootstrapAdmin(users *models.UserModel, email string) error {
+	if email == "" {
+		return nil
+	}
+
+	var id int
+	err := users.DB.QueryRow(`SELECT id FROM users WHERE email = ?`, email).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("bootstrap admin %s: %w", email, err)
+	}
+
+	err = users.SetRole(id, models.RoleAdmin)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		return fmt.Errorf("bootstrap admin %s: %w", email, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package main
+
+import "net/http"
+
+type contextKey string
+
+const isAuthenticatedContextKey = contextKey("isAuthenticated")
+
+// isAuthenticated reports whether the current request was made by an
+// authenticated user, as determined by the authenticate middleware
+func (app *application) isAuthenticated(r *http.Request) bool {
+	isAuthenticated, ok := r.Context().Value(isAuthenticatedContextKey).(bool)
+	if !ok {
+		return false
+	}
+
+	return isAuthenticated
+}
@@ -58,10 +58,18 @@ func (app *application) snippetView(w http.ResponseWriter, r *http.Request) {
 }
 
 func (app *application) snippetCreate(w http.ResponseWriter, r *http.Request) {
+	if !app.requireRole(w, r, models.RoleEditor) {
+		return
+	}
+
 	w.Write([]byte("Display a form creating a new snippet"))
 }
 
 func (app *application) snippetCreatePost(w http.ResponseWriter, r *http.Request) {
+	if !app.requireRole(w, r, models.RoleEditor) {
+		return
+	}
+
 	title := "O sanil"
 	content := "O sanil\nClimb Mount Fuji,\nBut slowly, slowly!\n\n- Kobayashi Issa"
 	expires := 7
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/justinas/nosurf"
+
+	"snipeetbox.porcelain.com/internal/models"
+)
+
+// authenticate looks up the authenticatedUserID in the session and, if it
+// still refers to an existing user, marks the request as authenticated via
+// the request context
+func (app *application) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := app.sessionManager.GetInt(r.Context(), "authenticatedUserID")
+		if id == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		exists, err := app.users.Exists(id)
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+
+		if exists {
+			ctx := context.WithValue(r.Context(), isAuthenticatedContextKey, true)
+			r = r.WithContext(ctx)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireAuthentication redirects unauthenticated requests to the login
+// page, remembering the original path so the user lands back there after
+// signing in
+func (app *application) requireAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.isAuthenticated(r) {
+			app.sessionManager.Put(r.Context(), "redirectPathAfterLogin", r.URL.Path)
+			http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+			return
+		}
+
+		w.Header().Add("Cache-Control", "no-store")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireRole 403s the request and returns false if the authenticated user's
+// role is below minRole in the user < editor < admin hierarchy, otherwise it
+// returns true. Callers must run this after requireAuthentication, so an
+// authenticatedUserID is already present in the session, and must stop
+// handling the request (return immediately) when it reports false.
+func (app *application) requireRole(w http.ResponseWriter, r *http.Request, minRole models.Role) bool {
+	id := app.sessionManager.GetInt(r.Context(), "authenticatedUserID")
+
+	role, err := app.users.GetRole(id)
+	if err != nil {
+		app.serverError(w, r, err)
+		return false
+	}
+
+	if !role.AtLeast(minRole) {
+		app.clientError(w, http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// requireHalfAuthentication guards the two-factor verification page: it's
+// only reachable for a session that has passed the password check but not
+// yet the TOTP check
+func (app *application) requireHalfAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.sessionManager.GetInt(r.Context(), "pendingTOTPUserID") == 0 {
+			http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// noSurf adds CSRF protection to state-changing requests using a cookie
+// scoped to the current session
+func noSurf(next http.Handler) http.Handler {
+	csrfHandler := nosurf.New(next)
+	csrfHandler.SetBaseCookie(http.Cookie{
+		HttpOnly: true,
+		Path:     "/",
+		Secure:   true,
+	})
+
+	return csrfHandler
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"snipeetbox.porcelain.com/internal/models"
+)
+
+// passwordResetRequest displays the form where a user enters their email
+// address to kick off a password reset
+func (app *application) passwordResetRequest(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, "Display a form for requesting a password reset")
+}
+
+// passwordResetRequestPost generates a reset token for the submitted email
+// and emails the reset link. It always responds the same way regardless of
+// whether the email matches an account, so the form can't be used to probe
+// which addresses are registered.
+func (app *application) passwordResetRequestPost(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	email := r.PostForm.Get("email")
+
+	token, _, err := app.users.CreatePasswordResetToken(email)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, r, err)
+		return
+	}
+
+	if err == nil {
+		resetURL := fmt.Sprintf("%s://%s/user/password/reset/%s", scheme(r), r.Host, token)
+		if err := app.mailer.SendPasswordReset(email, resetURL); err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	fmt.Fprint(w, "If that email address is registered, a password reset link has been sent")
+}
+
+// passwordResetForm displays the form where a user sets a new password,
+// after following the link from their reset email
+func (app *application) passwordResetForm(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+
+	if _, err := app.users.ValidatePasswordResetToken(token); err != nil {
+		if errors.Is(err, models.ErrInvalidToken) || errors.Is(err, models.ErrExpiredToken) {
+			app.clientError(w, http.StatusBadRequest)
+			return
+		}
+		app.serverError(w, r, err)
+		return
+	}
+
+	fmt.Fprintf(w, "Display a form for resetting the password for token %s", token)
+}
+
+// passwordResetPost validates the token and sets the user's new password
+func (app *application) passwordResetPost(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+
+	err := r.ParseForm()
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	newPassword := r.PostForm.Get("password")
+
+	err = app.users.ResetPassword(token, newPassword)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidToken) || errors.Is(err, models.ErrExpiredToken) {
+			app.clientError(w, http.StatusBadRequest)
+			return
+		}
+		app.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+}
+
+// scheme returns "https" if the request was served over TLS, otherwise "http"
+func scheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
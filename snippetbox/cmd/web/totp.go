@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"snipeetbox.porcelain.com/internal/models"
+)
+
+// userLoginTwoFactorForm displays the form for entering a TOTP code or
+// recovery code, for a session left half-authenticated by userLoginPost
+func (app *application) userLoginTwoFactorForm(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, "Display a form for entering your two-factor authentication code")
+}
+
+// userLoginTwoFactorPost completes a login that was left half-authenticated
+// after the password check, once the user presents a valid TOTP or recovery
+// code
+func (app *application) userLoginTwoFactorPost(w http.ResponseWriter, r *http.Request) {
+	id := app.sessionManager.GetInt(r.Context(), "pendingTOTPUserID")
+	if id == 0 {
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	ip := clientIP(r)
+
+	err = app.users.VerifyTOTP(id, code, ip)
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		err = app.users.ConsumeRecoveryCode(id, code, ip)
+	}
+	if err != nil {
+		if errors.Is(err, models.ErrAccountLocked) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, "Display the two-factor form again: too many failed attempts, try again later")
+			return
+		}
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, "Display the two-factor form again: code is incorrect")
+			return
+		}
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Remove(r.Context(), "pendingTOTPUserID")
+	app.sessionManager.Put(r.Context(), "authenticatedUserID", id)
+
+	http.Redirect(w, r, "/snippet/create", http.StatusSeeOther)
+}
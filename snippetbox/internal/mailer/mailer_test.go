@@ -0,0 +1,24 @@
+package mailer
+
+import "testing"
+
+func TestStripCRLF(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"no control characters", "alice@example.com", "alice@example.com"},
+		{"crlf injection", "alice@example.com\r\nBcc: victim@example.com", "alice@example.comBcc: victim@example.com"},
+		{"bare lf", "Reset your password\nX-Injected: true", "Reset your passwordX-Injected: true"},
+		{"bare cr", "Reset your password\rX-Injected: true", "Reset your passwordX-Injected: true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripCRLF(tt.in); got != tt.want {
+				t.Errorf("stripCRLF(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
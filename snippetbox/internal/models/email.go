@@ -0,0 +1,12 @@
+package models
+
+import "net/mail"
+
+// isValidEmail reports whether email parses as a single RFC 5322 address
+// with nothing left over. This rejects embedded CR/LF and other control
+// characters that could otherwise be used to smuggle extra mail headers or
+// SMTP commands through a stored email address.
+func isValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestIsValidEmail(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		want  bool
+	}{
+		{"plain address", "alice@example.com", true},
+		{"subaddress", "alice+tag@example.com", true},
+		{"crlf injection", "alice@example.com\r\nBcc: victim@example.com", false},
+		{"bare lf injection", "alice@example.com\nBcc: victim@example.com", false},
+		{"trailing garbage", "alice@example.com, Name <bob@example.com>", false},
+		{"missing at sign", "not-an-email", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidEmail(tt.email); got != tt.want {
+				t.Errorf("isValidEmail(%q) = %v, want %v", tt.email, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "errors"
+
+var (
+	// ErrNoRecord is returned when a database query finds no matching record
+	ErrNoRecord = errors.New("models: no matching record found")
+
+	// ErrInvalidCredentials is returned when a user attempts to authenticate
+	// with an incorrect email address or password
+	ErrInvalidCredentials = errors.New("models: invalid credentials")
+
+	// ErrDuplicateEmail is returned when a user tries to sign up with an
+	// email address that already exists in the database
+	ErrDuplicateEmail = errors.New("models: duplicate email")
+
+	// ErrInvalidToken is returned when a password reset token doesn't match
+	// any stored token hash
+	ErrInvalidToken = errors.New("models: invalid password reset token")
+
+	// ErrExpiredToken is returned when a password reset token is well-formed
+	// but has already expired or been used
+	ErrExpiredToken = errors.New("models: expired password reset token")
+
+	// ErrTOTPRequired is returned by Authenticate when the email and
+	// password are correct but the account has TOTP two-factor
+	// authentication enabled, so the login isn't complete yet
+	ErrTOTPRequired = errors.New("models: totp code required")
+
+	// ErrAccountLocked is returned by Authenticate when the (user, ip) pair
+	// has too many recent failed login attempts. The password isn't
+	// checked in this case.
+	ErrAccountLocked = errors.New("models: account temporarily locked")
+
+	// ErrInvalidEmail is returned when an email address fails to parse as a
+	// single RFC 5322 address, e.g. because it contains a CR/LF that could
+	// otherwise be used to inject extra mail headers or SMTP commands
+	ErrInvalidEmail = errors.New("models: invalid email address")
+)
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"net"
+	"time"
+)
+
+// dummyBcryptHash is a fixed, valid bcrypt hash of an arbitrary password. It
+// isn't tied to any real account - Authenticate compares against it purely
+// to burn the same amount of CPU time as a real password check would, when
+// the submitted email doesn't exist.
+const dummyBcryptHash = "$2a$12$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi"
+
+// pruneAttemptsOlderThan is how long failed and successful login attempts
+// are kept before PruneOldLoginAttempts deletes them
+const pruneAttemptsOlderThan = 30 * 24 * time.Hour
+
+// isLockedOut reports whether userID has too many failed login attempts
+// from ip within the configured window
+func (m *UserModel) isLockedOut(userID int, ip string) (bool, error) {
+	stmt := `SELECT COUNT(*) FROM user_login_attempts
+	WHERE user_id = ? AND ip = ? AND success = FALSE AND attempted_at > ?`
+
+	var count int
+	since := time.Now().Add(-m.LoginAttemptWindow)
+
+	err := m.DB.QueryRow(stmt, userID, ipToBytes(ip), since).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return exceedsLoginAttemptThreshold(count, m.LoginAttemptThreshold), nil
+}
+
+// exceedsLoginAttemptThreshold reports whether count failed attempts within
+// the lookback window is enough to lock the account out
+func exceedsLoginAttemptThreshold(count, threshold int) bool {
+	return count >= threshold
+}
+
+// recordLoginAttempt logs a login attempt for userID from ip. Errors are
+// deliberately not propagated to the caller - a failure to record an
+// attempt shouldn't block the login itself.
+func (m *UserModel) recordLoginAttempt(userID int, ip string, success bool) {
+	stmt := `INSERT INTO user_login_attempts (user_id, ip, attempted_at, success)
+	VALUES (?, ?, UTC_TIMESTAMP(), ?)`
+
+	m.DB.Exec(stmt, userID, ipToBytes(ip), success)
+}
+
+// UnlockAccount clears userID's recent failed login attempts, lifting any
+// active lockout early
+func (m *UserModel) UnlockAccount(id int) error {
+	_, err := m.DB.Exec(`DELETE FROM user_login_attempts WHERE user_id = ? AND success = FALSE`, id)
+	return err
+}
+
+// PruneOldLoginAttempts deletes login attempt rows older than 30 days. It's
+// intended to be called periodically from a background goroutine.
+func (m *UserModel) PruneOldLoginAttempts() error {
+	stmt := `DELETE FROM user_login_attempts WHERE attempted_at < ?`
+
+	_, err := m.DB.Exec(stmt, time.Now().Add(-pruneAttemptsOlderThan))
+	return err
+}
+
+// ipToBytes converts a dotted-quad or IPv6 address to its packed binary
+// form for storage in the ip VARBINARY(16) column. Unparseable input is
+// stored as-is so a malformed ip never turns into a crash.
+func ipToBytes(ip string) []byte {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return []byte(ip)
+	}
+	return []byte(parsed.To16())
+}
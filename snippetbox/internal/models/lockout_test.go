@@ -0,0 +1,67 @@
+package models
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestIPToBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   string
+		want net.IP
+	}{
+		{"ipv4", "203.0.113.7", net.ParseIP("203.0.113.7").To16()},
+		{"ipv6", "2001:db8::1", net.ParseIP("2001:db8::1").To16()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ipToBytes(tt.ip)
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("ipToBytes(%q) = %x, want %x", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPToBytesUnparseable(t *testing.T) {
+	got := ipToBytes("not-an-ip")
+	if string(got) != "not-an-ip" {
+		t.Errorf("ipToBytes(%q) = %q, want the input preserved as-is", "not-an-ip", got)
+	}
+}
+
+func TestExceedsLoginAttemptThreshold(t *testing.T) {
+	tests := []struct {
+		count     int
+		threshold int
+		want      bool
+	}{
+		{0, 5, false},
+		{4, 5, false},
+		{5, 5, true},
+		{6, 5, true},
+		{0, 0, true},
+	}
+
+	for _, tt := range tests {
+		got := exceedsLoginAttemptThreshold(tt.count, tt.threshold)
+		if got != tt.want {
+			t.Errorf("exceedsLoginAttemptThreshold(%d, %d) = %v, want %v", tt.count, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestDummyBcryptHashIsWellFormed(t *testing.T) {
+	// Authenticate relies on this comparison failing with a mismatch, not a
+	// hash-format error, so the dummy compare actually burns bcrypt time
+	// instead of short-circuiting.
+	err := bcrypt.CompareHashAndPassword([]byte(dummyBcryptHash), []byte("whatever the attacker submitted"))
+	if err != bcrypt.ErrMismatchedHashAndPassword {
+		t.Errorf("CompareHashAndPassword against dummyBcryptHash = %v, want bcrypt.ErrMismatchedHashAndPassword", err)
+	}
+}
@@ -0,0 +1,119 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2idHasher hashes passwords with Argon2id under the given parameters
+type Argon2idHasher struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+// argon2idFormat is the PHC-style encoding used for stored hashes:
+// $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>
+const argon2idFormat = "$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s"
+
+func (h Argon2idHasher) Hash(pw string) ([]byte, error) {
+	salt := make([]byte, h.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	key := argon2.IDKey([]byte(pw), salt, h.Time, h.Memory, h.Threads, h.KeyLen)
+
+	encoded := fmt.Sprintf(argon2idFormat, argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+
+	return []byte(encoded), nil
+}
+
+func (h Argon2idHasher) Compare(hash []byte, pw string) error {
+	return compare(hash, pw)
+}
+
+// NeedsRehash reports whether hash isn't an Argon2id hash, or was hashed
+// under different parameters than h
+func (h Argon2idHasher) NeedsRehash(hash []byte) bool {
+	params, _, _, err := parseArgon2idHash(hash)
+	if err != nil {
+		return true
+	}
+
+	return params.Time != h.Time || params.Memory != h.Memory ||
+		params.Threads != h.Threads || uint32(len(params.key)) != h.KeyLen
+}
+
+type argon2idParams struct {
+	version int
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+	key     []byte
+}
+
+// parseArgon2idHash splits a PHC-style Argon2id hash on "$" rather than
+// parsing it with fmt.Sscanf: Sscanf's %s only stops at whitespace, so it
+// can't tell where the salt segment ends and the hash segment begins when
+// both are butted up against a literal "$".
+func parseArgon2idHash(hash []byte) (argon2idParams, []byte, []byte, error) {
+	malformed := errors.New("password: malformed argon2id hash")
+
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return argon2idParams{}, nil, nil, malformed
+	}
+
+	var params argon2idParams
+	saltB64, keyB64 := parts[4], parts[5]
+
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &params.version); err != nil {
+		return argon2idParams{}, nil, nil, malformed
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return argon2idParams{}, nil, nil, malformed
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(saltB64)
+	if err != nil {
+		return argon2idParams{}, nil, nil, err
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(keyB64)
+	if err != nil {
+		return argon2idParams{}, nil, nil, err
+	}
+	params.key = key
+
+	return params, salt, key, nil
+}
+
+func argon2idCompare(hash []byte, pw string) error {
+	params, salt, key, err := parseArgon2idHash(hash)
+	if err != nil {
+		return err
+	}
+
+	candidate := argon2.IDKey([]byte(pw), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+
+	if subtle.ConstantTimeCompare(candidate, key) != 1 {
+		return ErrMismatchedHashAndPassword
+	}
+
+	return nil
+}
+
+// ErrMismatchedHashAndPassword is returned by Compare when pw doesn't match
+// the stored hash
+var ErrMismatchedHashAndPassword = errors.New("password: hash and password do not match")
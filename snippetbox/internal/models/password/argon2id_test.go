@@ -0,0 +1,38 @@
+package password
+
+import "testing"
+
+func TestArgon2idHasherHashAndCompare(t *testing.T) {
+	h := Argon2idHasher{Time: 1, Memory: 64 * 1024, Threads: 2, KeyLen: 32, SaltLen: 16}
+
+	hash, err := h.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash returned an error: %v", err)
+	}
+
+	if err := h.Compare(hash, "correct horse battery staple"); err != nil {
+		t.Errorf("Compare with the correct password returned an error: %v", err)
+	}
+
+	if err := h.Compare(hash, "wrong password"); err != ErrMismatchedHashAndPassword {
+		t.Errorf("Compare with the wrong password = %v, want ErrMismatchedHashAndPassword", err)
+	}
+}
+
+func TestArgon2idHasherNeedsRehash(t *testing.T) {
+	h := Argon2idHasher{Time: 1, Memory: 64 * 1024, Threads: 2, KeyLen: 32, SaltLen: 16}
+
+	hash, err := h.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash returned an error: %v", err)
+	}
+
+	if h.NeedsRehash(hash) {
+		t.Error("NeedsRehash = true for a hash just produced with the same parameters")
+	}
+
+	stronger := Argon2idHasher{Time: 2, Memory: 64 * 1024, Threads: 2, KeyLen: 32, SaltLen: 16}
+	if !stronger.NeedsRehash(hash) {
+		t.Error("NeedsRehash = false for a hash produced with weaker parameters")
+	}
+}
@@ -0,0 +1,30 @@
+package password
+
+import "golang.org/x/crypto/bcrypt"
+
+// BcryptHasher hashes passwords with bcrypt at the given cost
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h BcryptHasher) Hash(pw string) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(pw), h.Cost)
+}
+
+func (h BcryptHasher) Compare(hash []byte, pw string) error {
+	return compare(hash, pw)
+}
+
+// NeedsRehash reports whether hash isn't a bcrypt hash, or was hashed at a
+// lower cost than h.Cost
+func (h BcryptHasher) NeedsRehash(hash []byte) bool {
+	cost, err := bcrypt.Cost(hash)
+	if err != nil {
+		return true
+	}
+	return cost < h.Cost
+}
+
+func bcryptCompare(hash []byte, pw string) error {
+	return bcrypt.CompareHashAndPassword(hash, []byte(pw))
+}
@@ -0,0 +1,44 @@
+// Package password provides pluggable password hashing for UserModel, so the
+// hashing algorithm used for new passwords can change over time without
+// breaking verification of passwords hashed under an older algorithm.
+package password
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrUnknownHashFormat is returned when a stored hash doesn't match any
+// algorithm prefix this package knows how to verify
+var ErrUnknownHashFormat = errors.New("password: unknown hash format")
+
+// Hasher hashes and verifies passwords under a particular algorithm and
+// parameter set
+type Hasher interface {
+	// Hash returns a new hash of pw, encoded with an algorithm prefix that
+	// identifies how to verify it later
+	Hash(pw string) ([]byte, error)
+
+	// Compare reports whether pw matches hash. It dispatches on hash's
+	// algorithm prefix, so it can verify a hash produced by any Hasher in
+	// this package, not just the one Compare was called on.
+	Compare(hash []byte, pw string) error
+
+	// NeedsRehash reports whether hash should be replaced with a fresh hash
+	// from this Hasher, e.g. because it uses a different algorithm or
+	// weaker parameters
+	NeedsRehash(hash []byte) bool
+}
+
+// compare dispatches hash verification by inspecting hash's algorithm
+// prefix, regardless of which Hasher implementation it's called from
+func compare(hash []byte, pw string) error {
+	switch {
+	case bytes.HasPrefix(hash, []byte("$argon2id$")):
+		return argon2idCompare(hash, pw)
+	case bytes.HasPrefix(hash, []byte("$2a$")), bytes.HasPrefix(hash, []byte("$2b$")), bytes.HasPrefix(hash, []byte("$2y$")):
+		return bcryptCompare(hash, pw)
+	default:
+		return ErrUnknownHashFormat
+	}
+}
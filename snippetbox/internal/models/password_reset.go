@@ -0,0 +1,121 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+// passwordResetTokenTTL is how long a password reset token stays valid
+const passwordResetTokenTTL = 45 * time.Minute
+
+// CreatePasswordResetToken generates a one-time password reset token for the
+// user with the given email. The returned token is the user-facing,
+// base64url-encoded value; only its SHA-256 hash is stored so that a
+// database compromise doesn't leak live tokens.
+func (m *UserModel) CreatePasswordResetToken(email string) (string, time.Time, error) {
+	var userID int
+
+	err := m.DB.QueryRow(`SELECT id FROM users WHERE email = ?`, email).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", time.Time{}, ErrNoRecord
+		}
+		return "", time.Time{}, err
+	}
+
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		return "", time.Time{}, err
+	}
+	token := base64.URLEncoding.EncodeToString(rawToken)
+	tokenHash := sha256.Sum256([]byte(token))
+
+	expiresAt := time.Now().Add(passwordResetTokenTTL)
+
+	stmt := `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+	VALUES (?, ?, ?)`
+
+	_, err = m.DB.Exec(stmt, userID, tokenHash[:], expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// ValidatePasswordResetToken checks that token is unused and unexpired,
+// returning the ID of the user it was issued for
+func (m *UserModel) ValidatePasswordResetToken(token string) (int, error) {
+	tokenHash := sha256.Sum256([]byte(token))
+
+	var userID int
+	var expiresAt time.Time
+	var usedAt sql.NullTime
+
+	stmt := `SELECT user_id, expires_at, used_at FROM password_reset_tokens WHERE token_hash = ?`
+
+	err := m.DB.QueryRow(stmt, tokenHash[:]).Scan(&userID, &expiresAt, &usedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrInvalidToken
+		}
+		return 0, err
+	}
+
+	if usedAt.Valid || !expiresAt.After(time.Now()) {
+		return 0, ErrExpiredToken
+	}
+
+	return userID, nil
+}
+
+// ResetPassword validates token and, if it's still live, atomically marks it
+// used and updates the user's password hash
+func (m *UserModel) ResetPassword(token, newPassword string) error {
+	tokenHash := sha256.Sum256([]byte(token))
+
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var userID int
+	var expiresAt time.Time
+	var usedAt sql.NullTime
+
+	stmt := `SELECT user_id, expires_at, used_at FROM password_reset_tokens WHERE token_hash = ? FOR UPDATE`
+
+	err = tx.QueryRow(stmt, tokenHash[:]).Scan(&userID, &expiresAt, &usedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+
+	if usedAt.Valid || !expiresAt.After(time.Now()) {
+		return ErrExpiredToken
+	}
+
+	hashedPassword, err := m.Hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE password_reset_tokens SET used_at = UTC_TIMESTAMP() WHERE token_hash = ?`, tokenHash[:])
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE users SET hashed_password = ? WHERE id = ?`, string(hashedPassword), userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// Role identifies a user's access level
+type Role string
+
+const (
+	RoleUser   Role = "user"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// roleLevel ranks roles from least to most privileged, so access checks can
+// be expressed as "at least editor" rather than an exact match
+var roleLevel = map[Role]int{
+	RoleUser:   0,
+	RoleEditor: 1,
+	RoleAdmin:  2,
+}
+
+// AtLeast reports whether r is at or above min in the user < editor < admin
+// hierarchy
+func (r Role) AtLeast(min Role) bool {
+	return roleLevel[r] >= roleLevel[min]
+}
+
+// GetRole returns the role of the user with the given ID
+func (m *UserModel) GetRole(id int) (Role, error) {
+	var role Role
+
+	err := m.DB.QueryRow(`SELECT role FROM users WHERE id = ?`, id).Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNoRecord
+		}
+		return "", err
+	}
+
+	return role, nil
+}
+
+// SetRole updates the role of the user with the given ID
+func (m *UserModel) SetRole(id int, r Role) error {
+	result, err := m.DB.Exec(`UPDATE users SET role = ? WHERE id = ?`, r, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+// ListByRole retrieves all users that have the given role
+func (m *UserModel) ListByRole(r Role) ([]User, error) {
+	stmt := `SELECT id, name, email, role, created FROM users WHERE role = ? ORDER BY created DESC`
+
+	rows, err := m.DB.Query(stmt, r)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+
+	for rows.Next() {
+		var u User
+		err = rows.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.Created)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
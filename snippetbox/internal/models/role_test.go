@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestRoleAtLeast(t *testing.T) {
+	tests := []struct {
+		role Role
+		min  Role
+		want bool
+	}{
+		{RoleUser, RoleUser, true},
+		{RoleUser, RoleEditor, false},
+		{RoleUser, RoleAdmin, false},
+		{RoleEditor, RoleUser, true},
+		{RoleEditor, RoleEditor, true},
+		{RoleEditor, RoleAdmin, false},
+		{RoleAdmin, RoleUser, true},
+		{RoleAdmin, RoleEditor, true},
+		{RoleAdmin, RoleAdmin, true},
+	}
+
+	for _, tt := range tests {
+		got := tt.role.AtLeast(tt.min)
+		if got != tt.want {
+			t.Errorf("Role(%q).AtLeast(%q) = %v, want %v", tt.role, tt.min, got, tt.want)
+		}
+	}
+}
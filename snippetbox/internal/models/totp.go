@@ -0,0 +1,262 @@
+package models
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// recoveryCodeAlphabet excludes visually ambiguous characters (0/O, 1/I/l)
+const recoveryCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+const recoveryCodeCount = 10
+
+// totpValidateOpts configures RFC 6238 code validation: 30-second steps,
+// 6 digits, SHA-1, with ±1 step of clock skew tolerated. It's shared by
+// ConfirmTOTP and VerifyTOTP so both stages of the flow agree on what
+// counts as a valid code.
+var totpValidateOpts = totp.ValidateOpts{
+	Period:    30,
+	Skew:      1,
+	Digits:    6,
+	Algorithm: 0,
+}
+
+// EnrollTOTP generates a new TOTP secret for userID and stores it
+// unconfirmed, returning the raw secret and an otpauth:// URL suitable for
+// rendering as a QR code. The secret only takes effect once ConfirmTOTP
+// succeeds, so scanning it twice before confirming just replaces the
+// pending secret.
+func (m *UserModel) EnrollTOTP(userID int) (string, string, error) {
+	user, err := m.Get(userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      "SnippetBox",
+		AccountName: user.Email,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	stmt := `INSERT INTO user_totp (user_id, secret) VALUES (?, ?)
+	ON DUPLICATE KEY UPDATE secret = VALUES(secret), confirmed_at = NULL, recovery_codes_json = NULL`
+
+	_, err = m.DB.Exec(stmt, userID, key.Secret())
+	if err != nil {
+		return "", "", err
+	}
+
+	return key.Secret(), key.URL(), nil
+}
+
+// ConfirmTOTP verifies code against the pending secret enrolled by
+// EnrollTOTP and, if it matches, activates TOTP for the account and returns
+// a fresh batch of single-use recovery codes. The plain-text codes are
+// returned exactly once; only their bcrypt hashes are persisted.
+func (m *UserModel) ConfirmTOTP(userID int, code string) ([]string, error) {
+	secret, _, err := m.totpSecret(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := totp.ValidateCustom(code, secret, time.Now(), totpValidateOpts)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, ErrInvalidCredentials
+	}
+
+	codes, hashesJSON, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := `UPDATE user_totp SET confirmed_at = UTC_TIMESTAMP(), recovery_codes_json = ? WHERE user_id = ?`
+
+	_, err = m.DB.Exec(stmt, hashesJSON, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP turns off TOTP for userID, after verifying code against the
+// active secret
+func (m *UserModel) DisableTOTP(userID int, code, ip string) error {
+	err := m.VerifyTOTP(userID, code, ip)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.DB.Exec(`DELETE FROM user_totp WHERE user_id = ?`, userID)
+	return err
+}
+
+// VerifyTOTP checks code against the user's confirmed TOTP secret, allowing
+// for RFC 6238 clock skew of ±1 step. Like Authenticate, it counts failed
+// attempts per (user, ip) in user_login_attempts and returns
+// ErrAccountLocked once the threshold is exceeded, so the TOTP step can't be
+// brute-forced even by an attacker who already has the correct password.
+func (m *UserModel) VerifyTOTP(userID int, code, ip string) error {
+	locked, err := m.isLockedOut(userID, ip)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrAccountLocked
+	}
+
+	secret, confirmed, err := m.totpSecret(userID)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return ErrInvalidCredentials
+	}
+
+	valid, err := totp.ValidateCustom(code, secret, time.Now(), totpValidateOpts)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		m.recordLoginAttempt(userID, ip, false)
+		return ErrInvalidCredentials
+	}
+
+	m.recordLoginAttempt(userID, ip, true)
+	return nil
+}
+
+// ConsumeRecoveryCode checks code against the user's unused recovery codes.
+// A matching code is removed so it can't be reused. It shares the same
+// (user, ip) lockout counter as VerifyTOTP, since a recovery code is just
+// another way to complete the second factor.
+func (m *UserModel) ConsumeRecoveryCode(userID int, code, ip string) error {
+	locked, err := m.isLockedOut(userID, ip)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrAccountLocked
+	}
+
+	var hashesJSON []byte
+
+	err = m.DB.QueryRow(`SELECT recovery_codes_json FROM user_totp WHERE user_id = ? AND confirmed_at IS NOT NULL`, userID).
+		Scan(&hashesJSON)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidCredentials
+		}
+		return err
+	}
+
+	var hashes []string
+	if err := json.Unmarshal(hashesJSON, &hashes); err != nil {
+		return err
+	}
+
+	for i, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(code)) == nil {
+			hashes = append(hashes[:i], hashes[i+1:]...)
+
+			updated, err := json.Marshal(hashes)
+			if err != nil {
+				return err
+			}
+
+			_, err = m.DB.Exec(`UPDATE user_totp SET recovery_codes_json = ? WHERE user_id = ?`, updated, userID)
+			if err != nil {
+				return err
+			}
+
+			m.recordLoginAttempt(userID, ip, true)
+			return nil
+		}
+	}
+
+	m.recordLoginAttempt(userID, ip, false)
+	return ErrInvalidCredentials
+}
+
+// totpSecret returns the stored TOTP secret for userID and whether it has
+// been confirmed
+func (m *UserModel) totpSecret(userID int) (string, bool, error) {
+	var secret string
+	var confirmedAt sql.NullTime
+
+	err := m.DB.QueryRow(`SELECT secret, confirmed_at FROM user_totp WHERE user_id = ?`, userID).
+		Scan(&secret, &confirmedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, ErrNoRecord
+		}
+		return "", false, err
+	}
+
+	return secret, confirmedAt.Valid, nil
+}
+
+// hasConfirmedTOTP reports whether userID has an active TOTP secret
+func (m *UserModel) hasConfirmedTOTP(userID int) (bool, error) {
+	_, confirmed, err := m.totpSecret(userID)
+	if errors.Is(err, ErrNoRecord) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return confirmed, nil
+}
+
+// generateRecoveryCodes returns recoveryCodeCount fresh plain-text codes
+// along with their bcrypt hashes, JSON-encoded for storage
+func generateRecoveryCodes() ([]string, []byte, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		code, err := randomRecoveryCode(8)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes[i] = code
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, nil, err
+		}
+		hashes[i] = string(hash)
+	}
+
+	hashesJSON, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return codes, hashesJSON, nil
+}
+
+func randomRecoveryCode(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := make([]byte, length)
+	for i, v := range b {
+		code[i] = recoveryCodeAlphabet[int(v)%len(recoveryCodeAlphabet)]
+	}
+
+	return string(code), nil
+}
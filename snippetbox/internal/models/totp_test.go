@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestTOTPValidateOptsRoundTrip(t *testing.T) {
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "SnippetBox", AccountName: "test@example.com"})
+	if err != nil {
+		t.Fatalf("totp.Generate returned error: %v", err)
+	}
+
+	now := time.Now()
+
+	code, err := totp.GenerateCodeCustom(key.Secret(), now, totpValidateOpts)
+	if err != nil {
+		t.Fatalf("totp.GenerateCodeCustom returned error: %v", err)
+	}
+
+	valid, err := totp.ValidateCustom(code, key.Secret(), now, totpValidateOpts)
+	if err != nil {
+		t.Fatalf("totp.ValidateCustom returned error: %v", err)
+	}
+	if !valid {
+		t.Error("a freshly generated code did not validate against totpValidateOpts")
+	}
+
+	valid, err = totp.ValidateCustom("000000", key.Secret(), now, totpValidateOpts)
+	if err != nil {
+		t.Fatalf("totp.ValidateCustom returned error: %v", err)
+	}
+	if valid {
+		t.Error("an arbitrary wrong code validated against totpValidateOpts")
+	}
+}
+
+func TestRandomRecoveryCode(t *testing.T) {
+	code, err := randomRecoveryCode(8)
+	if err != nil {
+		t.Fatalf("randomRecoveryCode returned error: %v", err)
+	}
+
+	if len(code) != 8 {
+		t.Fatalf("len(code) = %d, want 8", len(code))
+	}
+
+	for _, c := range code {
+		if !strings.ContainsRune(recoveryCodeAlphabet, c) {
+			t.Errorf("code %q contains character %q outside recoveryCodeAlphabet", code, c)
+		}
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashesJSON, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes returned error: %v", err)
+	}
+
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("len(codes) = %d, want %d", len(codes), recoveryCodeCount)
+	}
+
+	var hashes []string
+	if err := json.Unmarshal(hashesJSON, &hashes); err != nil {
+		t.Fatalf("json.Unmarshal(hashesJSON) returned error: %v", err)
+	}
+
+	if len(hashes) != recoveryCodeCount {
+		t.Fatalf("len(hashes) = %d, want %d", len(hashes), recoveryCodeCount)
+	}
+
+	for i, code := range codes {
+		if err := bcrypt.CompareHashAndPassword([]byte(hashes[i]), []byte(code)); err != nil {
+			t.Errorf("hashes[%d] does not match codes[%d] %q: %v", i, i, code, err)
+		}
+	}
+}
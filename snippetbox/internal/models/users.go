@@ -8,6 +8,8 @@ import (
 
 	"github.com/go-sql-driver/mysql"
 	"golang.org/x/crypto/bcrypt"
+
+	pwd "snipeetbox.porcelain.com/internal/models/password"
 )
 
 // User represents a user in the system
@@ -16,18 +18,59 @@ type User struct {
 	Name           string
 	Email          string
 	HashedPassword []byte
+	Role           Role
 	Created        time.Time
 }
 
+// defaultHasher is used by NewUserModel when no Hasher is supplied, so
+// existing callers keep the bcrypt-at-cost-12 behaviour this package has
+// always had
+var defaultHasher = pwd.BcryptHasher{Cost: 12}
+
 // UserModel wraps a database connection pool
 type UserModel struct {
-	DB *sql.DB
+	DB     *sql.DB
+	Hasher pwd.Hasher
+
+	// LoginAttemptThreshold is how many failed logins a (user, ip) pair may
+	// make within LoginAttemptWindow before Authenticate starts returning
+	// ErrAccountLocked
+	LoginAttemptThreshold int
+
+	// LoginAttemptWindow is the lookback period used when counting recent
+	// failed login attempts
+	LoginAttemptWindow time.Duration
+}
+
+// defaultLoginAttemptThreshold and defaultLoginAttemptWindow bound
+// credential-stuffing attempts without locking out a user who's simply
+// mistyped their password a couple of times
+const (
+	defaultLoginAttemptThreshold = 5
+	defaultLoginAttemptWindow    = 15 * time.Minute
+)
+
+// NewUserModel returns a UserModel that hashes new passwords with hasher.
+// Passing a nil hasher falls back to bcrypt at cost 12.
+func NewUserModel(db *sql.DB, hasher pwd.Hasher) *UserModel {
+	if hasher == nil {
+		hasher = defaultHasher
+	}
+	return &UserModel{
+		DB:                    db,
+		Hasher:                hasher,
+		LoginAttemptThreshold: defaultLoginAttemptThreshold,
+		LoginAttemptWindow:    defaultLoginAttemptWindow,
+	}
 }
 
 // Insert adds a new user to the database with a hashed password
 func (m *UserModel) Insert(name, email, password string) error {
-	// Create a bcrypt hash of the plain-text password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if !isValidEmail(email) {
+		return ErrInvalidEmail
+	}
+
+	hashedPassword, err := m.Hasher.Hash(password)
 	if err != nil {
 		return err
 	}
@@ -50,9 +93,10 @@ func (m *UserModel) Insert(name, email, password string) error {
 	return nil
 }
 
-// Authenticate verifies whether a user exists with the provided email and password
-// This will return the relevant user ID if they do
-func (m *UserModel) Authenticate(email, password string) (int, error) {
+// Authenticate verifies whether a user exists with the provided email and
+// password, and records the attempt against ip for lockout purposes. This
+// will return the relevant user ID if they do.
+func (m *UserModel) Authenticate(email, password, ip string) (int, error) {
 	// Retrieve the id and hashed password associated with the given email
 	var id int
 	var hashedPassword []byte
@@ -62,22 +106,55 @@ func (m *UserModel) Authenticate(email, password string) (int, error) {
 	err := m.DB.QueryRow(stmt, email).Scan(&id, &hashedPassword)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
+			// Still do a dummy bcrypt comparison so that a login attempt
+			// against an email that doesn't exist takes roughly as long as
+			// one against a real account with a wrong password - otherwise
+			// response time would leak which emails are registered.
+			bcrypt.CompareHashAndPassword([]byte(dummyBcryptHash), []byte(password))
 			return 0, ErrInvalidCredentials
-		} else {
-			return 0, err
 		}
+		return 0, err
+	}
+
+	locked, err := m.isLockedOut(id, ip)
+	if err != nil {
+		return 0, err
+	}
+	if locked {
+		return 0, ErrAccountLocked
 	}
 
 	// Check whether the hashed password and plain-text password provided match
-	err = bcrypt.CompareHashAndPassword(hashedPassword, []byte(password))
+	err = m.Hasher.Compare(hashedPassword, password)
 	if err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) || errors.Is(err, pwd.ErrMismatchedHashAndPassword) {
+			m.recordLoginAttempt(id, ip, false)
 			return 0, ErrInvalidCredentials
-		} else {
-			return 0, err
 		}
+		return 0, err
 	}
 
+	// Opportunistically rehash with the current Hasher's parameters, e.g.
+	// after the bcrypt cost is raised or the algorithm is switched to
+	// Argon2id. Failure to rehash isn't fatal to the login attempt.
+	if m.Hasher.NeedsRehash(hashedPassword) {
+		if newHash, rehashErr := m.Hasher.Hash(password); rehashErr == nil {
+			m.DB.Exec(`UPDATE users SET hashed_password = ? WHERE id = ?`, string(newHash), id)
+		}
+	}
+
+	// If the account has TOTP enabled, the caller must still present a valid
+	// code before the login is considered complete
+	twoFactorEnabled, err := m.hasConfirmedTOTP(id)
+	if err != nil {
+		return 0, err
+	}
+	if twoFactorEnabled {
+		return id, ErrTOTPRequired
+	}
+
+	m.recordLoginAttempt(id, ip, true)
+
 	// Otherwise, the password is correct. Return the user ID
 	return id, nil
 }
@@ -96,9 +173,9 @@ func (m *UserModel) Exists(id int) (bool, error) {
 func (m *UserModel) Get(id int) (User, error) {
 	var u User
 
-	stmt := `SELECT id, name, email, created FROM users WHERE id = ?`
+	stmt := `SELECT id, name, email, role, created FROM users WHERE id = ?`
 
-	err := m.DB.QueryRow(stmt, id).Scan(&u.ID, &u.Name, &u.Email, &u.Created)
+	err := m.DB.QueryRow(stmt, id).Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.Created)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return User{}, ErrNoRecord
@@ -111,6 +188,10 @@ func (m *UserModel) Get(id int) (User, error) {
 
 // Update modifies an existing user's information
 func (m *UserModel) Update(id int, name, email string) error {
+	if !isValidEmail(email) {
+		return ErrInvalidEmail
+	}
+
 	stmt := `UPDATE users SET name = ?, email = ? WHERE id = ?`
 
 	_, err := m.DB.Exec(stmt, name, email, id)
@@ -143,16 +224,16 @@ func (m *UserModel) UpdatePassword(id int, currentPassword, newPassword string)
 	}
 
 	// Check if the current password is correct
-	err = bcrypt.CompareHashAndPassword(hashedPassword, []byte(currentPassword))
+	err = m.Hasher.Compare(hashedPassword, currentPassword)
 	if err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) || errors.Is(err, pwd.ErrMismatchedHashAndPassword) {
 			return ErrInvalidCredentials
 		}
 		return err
 	}
 
 	// Hash the new password
-	newHashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), 12)
+	newHashedPassword, err := m.Hasher.Hash(newPassword)
 	if err != nil {
 		return err
 	}
@@ -188,7 +269,7 @@ func (m *UserModel) Delete(id int) error {
 
 // List retrieves all users (useful for admin functionality)
 func (m *UserModel) List() ([]User, error) {
-	stmt := `SELECT id, name, email, created FROM users ORDER BY created DESC`
+	stmt := `SELECT id, name, email, role, created FROM users ORDER BY created DESC`
 
 	rows, err := m.DB.Query(stmt)
 	if err != nil {
@@ -200,7 +281,7 @@ func (m *UserModel) List() ([]User, error) {
 
 	for rows.Next() {
 		var u User
-		err = rows.Scan(&u.ID, &u.Name, &u.Email, &u.Created)
+		err = rows.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.Created)
 		if err != nil {
 			return nil, err
 		}